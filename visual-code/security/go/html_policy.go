@@ -0,0 +1,251 @@
+// File: /visual-code/security/go/html_policy.go
+// Platform: Windows/Linux/Ubuntu, Android/iOS (Go ≥ 1.20)
+// Language: Go (sanitized, production-grade)
+
+package security
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// HTMLPolicy describes which tags and attributes survive SanitizeHTMLAllowing
+// / SanitizeStream, and how href/src-style attribute values are filtered.
+type HTMLPolicy struct {
+	// AllowedTags is the set of element names kept verbatim; anything not
+	// listed here is dropped (its text content is kept, its tags are not).
+	AllowedTags map[string]bool
+	// BlockedTags are dropped along with their entire subtree (text
+	// included), taking priority over AllowedTags.
+	BlockedTags map[string]bool
+	// AllowedAttrs maps a tag name to the set of attribute names kept on
+	// that tag. A "*" key applies to every allowed tag.
+	AllowedAttrs map[string]map[string]bool
+	// URLAttrs names attributes (e.g. "href", "src") whose values are run
+	// through URLPolicy before being kept.
+	URLAttrs map[string]bool
+	// URLPolicy filters the values of URLAttrs. If nil, URL attributes are
+	// kept with their value replaced by the defang sentinel.
+	URLPolicy *URLPolicy
+	// StripAllTags, when set, discards every tag (AllowedTags is ignored)
+	// and keeps only text content. Used by StrictTextPolicy.
+	StripAllTags bool
+	// MaxBytes bounds how much of src SanitizeStream will read, via
+	// io.LimitReader, before treating the rest as truncated input. Zero
+	// defaults to MaxPromptBytes.
+	MaxBytes int64
+}
+
+// defaultBlockedTags mirrors the tags the previous regex-only sanitizer
+// special-cased, plus a few more that have no business in model output.
+var defaultBlockedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true,
+	"embed": true, "meta": true, "link": true, "frame": true,
+	"frameset": true, "applet": true, "base": true,
+}
+
+// DefaultHTMLPolicy allows a conservative set of formatting tags suitable
+// for rendering Markdown-derived model output, with href/src values
+// filtered through ImagePolicy's scheme allowlist.
+var DefaultHTMLPolicy = &HTMLPolicy{
+	AllowedTags: map[string]bool{
+		"p": true, "br": true, "hr": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+		"ul": true, "ol": true, "li": true,
+		"a": true, "img": true,
+		"code": true, "pre": true, "blockquote": true,
+		"b": true, "strong": true, "i": true, "em": true, "u": true,
+		"table": true, "thead": true, "tbody": true, "tr": true, "td": true, "th": true,
+	},
+	BlockedTags: defaultBlockedTags,
+	AllowedAttrs: map[string]map[string]bool{
+		"*":   {"title": true},
+		"a":   {"href": true},
+		"img": {"src": true, "alt": true},
+	},
+	URLAttrs:  map[string]bool{"href": true, "src": true},
+	URLPolicy: ImagePolicy,
+}
+
+// StrictTextPolicy strips every tag and keeps only text content, which is
+// HTML-escaped like the text of every policy. SanitizePromptForVision uses
+// it in place of its former regex-based tag stripping; unlike that, removed
+// tags are not replaced by a space. BlockedTags is still set
+// so raw-text elements (script, style, ...) have their body dropped along
+// with the tag, rather than leaking their contents as plain text.
+var StrictTextPolicy = &HTMLPolicy{StripAllTags: true, BlockedTags: defaultBlockedTags}
+
+// sanitizerSentinel is written in place of a defanged URL attribute value,
+// matching the convention html/template uses for unsafe-scheme hrefs.
+const sanitizerSentinel = "#ZgotmplZ"
+
+// SanitizeHTMLAllowing walks input with an HTML tokenizer and rewrites it
+// according to policy: blocked tags are dropped along with their subtree,
+// tags outside AllowedTags are dropped but their text is kept, surviving
+// tags keep only allowlisted attributes, and URLAttrs values are passed
+// through policy.URLPolicy (or defanged to a sentinel if no policy, or if
+// the value is rejected).
+func SanitizeHTMLAllowing(input string, policy *HTMLPolicy) (string, error) {
+	if policy == nil {
+		return "", errors.New("nil HTML policy")
+	}
+	var buf bytes.Buffer
+	if _, err := SanitizeStream(&buf, strings.NewReader(input), policy); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// SanitizeStream is the streaming form of SanitizeHTMLAllowing: it tokenizes
+// src incrementally and writes the filtered result to dst, so callers never
+// need to hold the full input in memory. src is bounded by policy.MaxBytes
+// (or MaxPromptBytes if unset) via io.LimitReader, and text content is
+// control-char-stripped and whitespace-collapsed in the same pass, so
+// callers using SanitizeStream/SanitizeHTMLAllowing directly don't need to
+// redo that work themselves. It returns the number of bytes written to dst.
+func SanitizeStream(dst io.Writer, src io.Reader, policy *HTMLPolicy) (int64, error) {
+	if policy == nil {
+		return 0, errors.New("nil HTML policy")
+	}
+	limit := policy.MaxBytes
+	if limit <= 0 {
+		limit = MaxPromptBytes
+	}
+	z := html.NewTokenizer(io.LimitReader(src, limit))
+	var written int64
+	// blockDepth counts nested occurrences of the currently-skipped blocked
+	// tag, so e.g. nested <script><script> content is dropped as one unit.
+	blockDepth := 0
+	var blockTag string
+	// lastWasSpace persists across text tokens so whitespace collapsing
+	// works across tag boundaries, not just within a single text run.
+	lastWasSpace := true
+
+	write := func(s string) error {
+		n, err := io.WriteString(dst, s)
+		written += int64(n)
+		return err
+	}
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if err := z.Err(); err != nil && err != io.EOF {
+				return written, err
+			}
+			return written, nil
+		}
+
+		tok := z.Token()
+
+		if blockDepth > 0 {
+			if tt == html.StartTagToken && tok.Data == blockTag {
+				blockDepth++
+			} else if tt == html.EndTagToken && tok.Data == blockTag {
+				blockDepth--
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken, html.CommentToken, html.DoctypeToken:
+			if tt == html.CommentToken || tt == html.DoctypeToken {
+				continue
+			}
+			cleaned := stripControlCollapsing(tok.Data, &lastWasSpace)
+			if err := write(html.EscapeString(cleaned)); err != nil {
+				return written, err
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name := tok.Data
+			if policy.BlockedTags[name] {
+				if tt == html.StartTagToken {
+					blockDepth = 1
+					blockTag = name
+				}
+				continue
+			}
+			if policy.StripAllTags || !policy.AllowedTags[name] {
+				continue
+			}
+			if err := write(renderTag(tok, policy, tt == html.SelfClosingTagToken)); err != nil {
+				return written, err
+			}
+
+		case html.EndTagToken:
+			name := tok.Data
+			if policy.BlockedTags[name] || policy.StripAllTags || !policy.AllowedTags[name] {
+				continue
+			}
+			if err := write(fmt.Sprintf("</%s>", name)); err != nil {
+				return written, err
+			}
+		}
+	}
+}
+
+// stripControlCollapsing removes ASCII control characters from a text node
+// (treating \n, \r, \t as whitespace) and collapses runs of whitespace to a
+// single space, carrying collapsing state in lastWasSpace so the result is
+// correct across the tag boundaries between successive text nodes.
+func stripControlCollapsing(s string, lastWasSpace *bool) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r == '\n' || r == '\r' || r == '\t' {
+			r = ' '
+		} else if r < 0x20 || r == 0x7f {
+			continue
+		}
+		if unicode.IsSpace(r) {
+			if *lastWasSpace {
+				continue
+			}
+			*lastWasSpace = true
+			b.WriteRune(' ')
+			continue
+		}
+		*lastWasSpace = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// renderTag re-serializes a start/self-closing tag after filtering its
+// attributes through policy.
+func renderTag(tok html.Token, policy *HTMLPolicy, selfClosing bool) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	b.WriteString(tok.Data)
+	allowed := policy.AllowedAttrs[tok.Data]
+	wildcard := policy.AllowedAttrs["*"]
+	for _, attr := range tok.Attr {
+		if !allowed[attr.Key] && !wildcard[attr.Key] {
+			continue
+		}
+		val := attr.Val
+		if policy.URLAttrs[attr.Key] {
+			if policy.URLPolicy == nil {
+				val = sanitizerSentinel
+			} else if u, err := SanitizeURL(val, policy.URLPolicy); err != nil {
+				val = sanitizerSentinel
+			} else {
+				val = u.String()
+			}
+		}
+		fmt.Fprintf(&b, " %s=\"%s\"", attr.Key, html.EscapeString(val))
+	}
+	if selfClosing {
+		b.WriteString(" />")
+	} else {
+		b.WriteByte('>')
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+// File: /visual-code/security/go/html_policy_test.go
+// Platform: Windows/Linux/Ubuntu, Android/iOS (Go ≥ 1.20)
+// Language: Go (sanitized, production-grade)
+
+package security
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestSanitizeHTMLAllowing(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		policy *HTMLPolicy
+		want   string
+	}{
+		{
+			name:   "strict policy drops all tags but keeps text",
+			input:  "<p>hello <b>world</b></p>",
+			policy: StrictTextPolicy,
+			want:   "hello world",
+		},
+		{
+			name:   "strict policy drops script tag and its body",
+			input:  "before<script>alert(document.cookie)</script>after",
+			policy: StrictTextPolicy,
+			want:   "beforeafter",
+		},
+		{
+			name:   "default policy keeps allowed tags and drops unknown tags",
+			input:  `<p>hi</p><unknown>drop tag keep text</unknown>`,
+			policy: DefaultHTMLPolicy,
+			want:   `<p>hi</p>drop tag keep text`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SanitizeHTMLAllowing(tc.input, tc.policy)
+			if err != nil {
+				t.Fatalf("SanitizeHTMLAllowing(%q) returned error: %v", tc.input, err)
+			}
+			if got != tc.want {
+				t.Fatalf("SanitizeHTMLAllowing(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestRenderTagEscapesAttributeValues guards against the attribute-injection
+// bug where a quote character embedded in an attribute value (reachable
+// from a single-quoted or unquoted source attribute) broke out of the
+// regenerated double-quoted attribute. renderTag must HTML-escape, not
+// Go-quote, every attribute value.
+func TestRenderTagEscapesAttributeValues(t *testing.T) {
+	tok := html.Token{
+		Type: html.StartTagToken,
+		Data: "img",
+		Attr: []html.Attribute{
+			{Key: "alt", Val: `x" onmouseover="alert(1)`},
+		},
+	}
+	got := renderTag(tok, DefaultHTMLPolicy, false)
+	if strings.Contains(got, `onmouseover="alert`) {
+		t.Fatalf("renderTag(%+v) = %q, attribute value broke out of its quotes", tok, got)
+	}
+	want := `<img alt="x&#34; onmouseover=&#34;alert(1)">`
+	if got != want {
+		t.Fatalf("renderTag(%+v) = %q, want %q", tok, got, want)
+	}
+}
+
+func TestSanitizeStreamEnforcesMaxBytes(t *testing.T) {
+	policy := &HTMLPolicy{StripAllTags: true, MaxBytes: 10}
+	var buf bytes.Buffer
+	n, err := SanitizeStream(&buf, strings.NewReader(strings.Repeat("a", 1000)), policy)
+	if err != nil {
+		t.Fatalf("SanitizeStream returned error: %v", err)
+	}
+	if n > 10 {
+		t.Fatalf("SanitizeStream wrote %d bytes, want <= policy.MaxBytes (10)", n)
+	}
+}
+
+// FuzzSanitizeStream feeds random bytes and malformed HTML fragments to
+// confirm no panic, no hang, and no unescaped tag leakage past the
+// sanitizer.
+func FuzzSanitizeStream(f *testing.F) {
+	seeds := []string{
+		"",
+		"<script>alert(1)</script>",
+		"<SCRIPT/XSS SRC=x>",
+		"<img src=x onerror=alert(1)>",
+		"<<<<<<<<<<<<<<<<",
+		"<a href=\"javascript:alert(1)\">click</a>",
+		"unterminated <tag attr=\"value",
+		strings.Repeat("<p>", 5000),
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		var buf bytes.Buffer
+		if _, err := SanitizeStream(&buf, strings.NewReader(input), DefaultHTMLPolicy); err != nil {
+			t.Fatalf("SanitizeStream returned error on input %q: %v", input, err)
+		}
+		out := strings.ToLower(buf.String())
+		for tag := range DefaultHTMLPolicy.BlockedTags {
+			if strings.Contains(out, "<"+tag) {
+				t.Fatalf("SanitizeStream(%q) = %q, blocked tag <%s> leaked", input, buf.String(), tag)
+			}
+		}
+	})
+}
+
+func benchmarkSanitizeStream(b *testing.B, size int) {
+	input := strings.Repeat("<p>hello <b>world</b> <script>alert(1)</script></p> ", 1)
+	for len(input) < size {
+		input += input
+	}
+	input = input[:size]
+	policy := &HTMLPolicy{MaxBytes: int64(size) + 1, AllowedTags: DefaultHTMLPolicy.AllowedTags, BlockedTags: DefaultHTMLPolicy.BlockedTags, AllowedAttrs: DefaultHTMLPolicy.AllowedAttrs}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = SanitizeStream(io.Discard, strings.NewReader(input), policy)
+	}
+}
+
+func BenchmarkSanitizeStream1KB(b *testing.B)   { benchmarkSanitizeStream(b, 1<<10) }
+func BenchmarkSanitizeStream100KB(b *testing.B) { benchmarkSanitizeStream(b, 100<<10) }
+func BenchmarkSanitizeStream1MB(b *testing.B)   { benchmarkSanitizeStream(b, 1<<20) }
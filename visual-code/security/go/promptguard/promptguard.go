@@ -0,0 +1,278 @@
+// File: /visual-code/security/go/promptguard/promptguard.go
+// Platform: Windows/Linux/Ubuntu, Android/iOS (Go ≥ 1.20)
+// Language: Go (sanitized, production-grade)
+
+// Package promptguard detects prompt-injection and jailbreak attempts in
+// user-supplied text before it reaches a VL/IG model prompt. It is invoked
+// optionally from security.SanitizePromptForVision.
+package promptguard
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Severity classifies how a scanned prompt should be treated.
+type Severity int
+
+const (
+	// SeverityAllow means no detector fired above its threshold.
+	SeverityAllow Severity = iota
+	// SeverityRedact means matched spans should be replaced and the
+	// redacted text used in place of the original.
+	SeverityRedact
+	// SeverityBlock means the prompt should be rejected outright.
+	SeverityBlock
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityAllow:
+		return "allow"
+	case SeverityRedact:
+		return "redact"
+	case SeverityBlock:
+		return "block"
+	default:
+		return "unknown"
+	}
+}
+
+// OnDetectAction tells ScanPrompt's caller what to do when SeverityRedact
+// is produced; SeverityBlock prompts are rejected regardless.
+type OnDetectAction int
+
+const (
+	// OnDetectRedact has the caller substitute Report.Redacted.
+	OnDetectRedact OnDetectAction = iota
+	// OnDetectError has the caller return an error instead of the prompt.
+	OnDetectError
+)
+
+// PromptPolicy configures ScanPrompt's thresholds and caller behavior.
+type PromptPolicy struct {
+	// OnDetect selects what SanitizePromptForVision-style callers do when
+	// Report.Severity is SeverityRedact (SeverityBlock is always rejected).
+	OnDetect OnDetectAction
+	// BlockThreshold is the minimum per-category score (0-100) that
+	// escalates a category from Redact to Block.
+	BlockThreshold int
+}
+
+// DefaultPromptPolicy redacts on any detector hit and escalates to Block
+// once a category's score reaches 80.
+var DefaultPromptPolicy = &PromptPolicy{
+	OnDetect:       OnDetectRedact,
+	BlockThreshold: 80,
+}
+
+// Category names the detector that produced a match.
+type Category string
+
+const (
+	CategoryJailbreak    Category = "jailbreak"
+	CategoryFakeChatTurn Category = "fake_chat_turn"
+	CategoryNSFW         Category = "nsfw"
+	CategoryExfiltration Category = "exfiltration"
+)
+
+// Match is a single detector hit within the scanned text.
+type Match struct {
+	Category Category
+	Start    int
+	End      int
+	Text     string
+}
+
+// Report is the result of ScanPrompt.
+type Report struct {
+	Severity Severity
+	// Scores maps each category that produced at least one match to a
+	// 0-100 severity score (10 points per match, capped at 100).
+	Scores  map[Category]int
+	Matches []Match
+	// Redacted is the normalized input (see normalize) with every matched
+	// span replaced by "[redacted]".
+	Redacted string
+}
+
+// zero-width and bidi-override characters stripped before detection, since
+// they're a common homoglyph/obfuscation vector in injection payloads.
+var invisibleChars = func() map[rune]bool {
+	m := map[rune]bool{
+		'\u200b': true, // zero-width space
+		'\u200c': true, // zero-width non-joiner
+		'\u200d': true, // zero-width joiner
+		'\ufeff': true, // zero-width no-break space / BOM
+	}
+	for r := rune(0x202A); r <= 0x202E; r++ {
+		m[r] = true
+	}
+	for r := rune(0x2066); r <= 0x2069; r++ {
+		m[r] = true
+	}
+	return m
+}()
+
+// homoglyphFold maps common Cyrillic/Greek look-alikes to their ASCII Latin
+// equivalent so detectors can't be evaded by substitution. Fullwidth forms
+// are not listed here; NFKC in normalize already folds them to ASCII.
+var homoglyphFold = map[rune]rune{
+	'а': 'a', 'А': 'A', // Cyrillic a
+	'е': 'e', 'Е': 'E', // Cyrillic ie
+	'о': 'o', 'О': 'O', // Cyrillic o
+	'р': 'p', 'Р': 'P', // Cyrillic er
+	'с': 'c', 'С': 'C', // Cyrillic es
+	'у': 'y', 'У': 'Y', // Cyrillic u
+	'х': 'x', 'Х': 'X', // Cyrillic ha
+	'і': 'i', 'І': 'I', // Cyrillic/Ukrainian i
+	'α': 'a', 'Α': 'A', // Greek alpha
+	'ο': 'o', 'Ο': 'O', // Greek omicron
+	'ρ': 'p', 'Ρ': 'P', // Greek rho
+	'ι': 'i', 'Ι': 'I', // Greek iota
+}
+
+// normalize applies NFKC, folds homoglyphs, and strips invisible/bidi
+// control characters, returning text ready for detector matching.
+func normalize(s string) string {
+	s = norm.NFKC.String(s)
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if invisibleChars[r] {
+			continue
+		}
+		if f, ok := homoglyphFold[r]; ok {
+			r = f
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var jailbreakPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)ignore\s+(all\s+)?(the\s+)?(previous|prior|above)\s+instructions`),
+	regexp.MustCompile(`(?i)disregard\s+(the\s+)?system\s+prompt`),
+	regexp.MustCompile(`(?i)\bdan\b\s*(mode)?`),
+	regexp.MustCompile(`(?i)developer\s+mode`),
+	regexp.MustCompile(`(?i)you\s+are\s+now\s+(the\s+)?(system|assistant)`),
+	regexp.MustCompile(`(?i)act\s+as\s+(the\s+)?(system|assistant)`),
+	regexp.MustCompile(`(?i)pretend\s+(you('re| are)|to\s+be)\s+(the\s+)?(system|assistant)`),
+}
+
+var fakeChatTurnPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`<\|im_start\|>`),
+	regexp.MustCompile(`<\|im_end\|>`),
+	regexp.MustCompile(`(?im)^\s*###\s*system\b`),
+	// Anchored to the start of a line (or the whole text) so a role-prefix
+	// turn like "system: ..." trips this, but ordinary prose mentioning
+	// "solar system:", "operating system:", etc. mid-sentence does not.
+	regexp.MustCompile(`(?im)^\s*system\s*:\s*`),
+}
+
+// nsfwWords is matched on word boundaries (\b) so substrings inside
+// unrelated words, e.g. "ass" inside "classification", no longer trip.
+var nsfwWords = regexp.MustCompile(`(?i)\b(nsfw|nude|nudity|porn|explicit|sexual|erotic|fetish)\b`)
+
+var exfiltrationPattern = regexp.MustCompile(`(?i)\b(curl|wget|fetch)\s*\(?\s*['"]?(https?|ftp|data):`)
+
+// ScanPrompt normalizes text and runs the layered detector bank against it,
+// returning a Report with the highest severity reached across all
+// categories and a redacted copy with matched spans replaced.
+func ScanPrompt(text string, pol *PromptPolicy) (Report, error) {
+	if pol == nil {
+		return Report{}, errors.New("nil prompt policy")
+	}
+	normalized := normalize(text)
+
+	var matches []Match
+	matches = append(matches, findMatches(normalized, CategoryJailbreak, jailbreakPatterns)...)
+	matches = append(matches, findMatches(normalized, CategoryFakeChatTurn, fakeChatTurnPatterns)...)
+	matches = append(matches, findMatches(normalized, CategoryNSFW, []*regexp.Regexp{nsfwWords})...)
+	matches = append(matches, findMatches(normalized, CategoryExfiltration, []*regexp.Regexp{exfiltrationPattern})...)
+
+	scores := map[Category]int{}
+	for _, m := range matches {
+		score := scores[m.Category] + 10
+		if score > 100 {
+			score = 100
+		}
+		scores[m.Category] = score
+	}
+
+	severity := SeverityAllow
+	if len(matches) > 0 {
+		severity = SeverityRedact
+	}
+	for _, score := range scores {
+		if score >= pol.BlockThreshold {
+			severity = SeverityBlock
+		}
+	}
+
+	return Report{
+		Severity: severity,
+		Scores:   scores,
+		Matches:  matches,
+		Redacted: redact(normalized, matches),
+	}, nil
+}
+
+// findMatches runs each pattern against text and records every match as a
+// Match tagged with category.
+func findMatches(text string, category Category, patterns []*regexp.Regexp) []Match {
+	var out []Match
+	for _, p := range patterns {
+		for _, loc := range p.FindAllStringIndex(text, -1) {
+			out = append(out, Match{
+				Category: category,
+				Start:    loc[0],
+				End:      loc[1],
+				Text:     text[loc[0]:loc[1]],
+			})
+		}
+	}
+	return out
+}
+
+// redact replaces every matched span in text with "[redacted]", merging
+// overlapping spans so nested detector hits don't produce doubled markers.
+func redact(text string, matches []Match) string {
+	if len(matches) == 0 {
+		return text
+	}
+	spans := make([][2]int, len(matches))
+	for i, m := range matches {
+		spans[i] = [2]int{m.Start, m.End}
+	}
+	for i := 1; i < len(spans); i++ {
+		for j := i; j > 0 && spans[j-1][0] > spans[j][0]; j-- {
+			spans[j-1], spans[j] = spans[j], spans[j-1]
+		}
+	}
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s[0] <= last[1] {
+			if s[1] > last[1] {
+				last[1] = s[1]
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	prev := 0
+	for _, s := range merged {
+		b.WriteString(text[prev:s[0]])
+		b.WriteString("[redacted]")
+		prev = s[1]
+	}
+	b.WriteString(text[prev:])
+	return b.String()
+}
@@ -7,9 +7,12 @@ package security
 import (
 	"errors"
 	"html"
-	"regexp"
+	"path"
+	"path/filepath"
 	"strings"
 	"unicode"
+
+	"github.com/Doctor0Evil/Visual-Code/security/go/promptguard"
 )
 
 // Hard size caps for user-controlled strings.
@@ -36,14 +39,13 @@ func stripControl(s string) string {
 	return b.String()
 }
 
-var htmlTagPattern = regexp.MustCompile(`(?is)<(script|style|iframe|object|embed|meta|link)[^>]*>.*?</\s*(script|style|iframe|object|embed)\s*>`)
-var angleTagPattern = regexp.MustCompile(`(?is)<[^>]{1,256}>`)
-
 // SanitizePromptForVision normalizes user text for VL/IG prompts:
 // - Enforces length.
-// - Removes dangerous HTML/script content.
+// - Strips all HTML/script content via StrictTextPolicy (tokenizer-based).
 // - Collapses whitespace.
 // - Blocks common NSFW tokens.
+// - Runs the prompt-injection/jailbreak scanner and redacts or rejects it
+//   per DefaultPromptPolicy.
 func SanitizePromptForVision(in string) (string, error) {
 	raw := strings.TrimSpace(in)
 	if len(raw) == 0 {
@@ -55,15 +57,17 @@ func SanitizePromptForVision(in string) (string, error) {
 
 	step1 := stripControl(raw)
 	step2 := html.UnescapeString(step1)
-	step3 := htmlTagPattern.ReplaceAllString(step2, " ")
-	step4 := angleTagPattern.ReplaceAllString(step3, " ")
-	step5 := collapseWhitespace(step4)
+	step3, err := SanitizeHTMLAllowing(step2, StrictTextPolicy)
+	if err != nil {
+		return "", err
+	}
+	step4 := collapseWhitespace(step3)
 
 	// Block NSFW markers.
 	blockPatterns := []string{
 		"nsfw", "nude", "nudity", "porn", "explicit", "sexual", "erotic",
 	}
-	safe := step5
+	safe := step4
 	lower := strings.ToLower(safe)
 	for _, token := range blockPatterns {
 		if strings.Contains(lower, token) {
@@ -76,6 +80,22 @@ func SanitizePromptForVision(in string) (string, error) {
 	if len(safe) == 0 {
 		return "", errors.New("prompt sanitized to empty")
 	}
+
+	report, err := promptguard.ScanPrompt(safe, promptguard.DefaultPromptPolicy)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case report.Severity == promptguard.SeverityBlock:
+		return "", errors.New("prompt blocked: possible prompt injection")
+	case report.Severity == promptguard.SeverityRedact && promptguard.DefaultPromptPolicy.OnDetect == promptguard.OnDetectError:
+		return "", errors.New("prompt rejected: possible prompt injection")
+	case report.Severity == promptguard.SeverityRedact:
+		safe = collapseWhitespace(report.Redacted)
+		if len(safe) == 0 {
+			return "", errors.New("prompt sanitized to empty")
+		}
+	}
 	return safe, nil
 }
 
@@ -126,29 +146,77 @@ func SanitizeFilename(name string) (string, error) {
 	if len(out) == 0 {
 		return "", errors.New("filename sanitized to empty")
 	}
+	if isReservedWindowsName(out) {
+		return "", errors.New("filename is a reserved Windows device name")
+	}
 	return out, nil
 }
 
-// SanitizeImageURL enforces a safe HTTP(S) URL with a max length.
-func SanitizeImageURL(u string) (string, error) {
-	raw := strings.TrimSpace(u)
-	if len(raw) == 0 {
-		return "", errors.New("empty url")
+// reservedWindowsNames are device names Windows refuses to open as regular
+// files, with or without an extension (e.g. "NUL" and "NUL.txt" both fail).
+var reservedWindowsNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// isReservedWindowsName reports whether name (before any extension) matches
+// a reserved Windows device name, case-insensitively. This module targets
+// Windows per the package header, so SanitizeFilename must reject these even
+// though they contain no path separators.
+func isReservedWindowsName(name string) bool {
+	base := name
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
 	}
-	if len(raw) > MaxURLBytes {
-		return "", errors.New("url too long")
+	return reservedWindowsNames[strings.ToUpper(base)]
+}
+
+// ResolveUnderRoot resolves a possibly-multi-segment, user-controlled path
+// under root, rejecting traversal. It cleans userPath, rejects absolute
+// paths, drive letters, NUL bytes, and any ".." segment left after
+// cleaning, then joins under root and verifies the result still has root
+// as a prefix. Callers that also need to defend against symlink escapes
+// should additionally resolve the result through filepath.EvalSymlinks.
+func ResolveUnderRoot(root, userPath string) (string, error) {
+	if strings.IndexByte(userPath, 0) >= 0 {
+		return "", errors.New("path contains NUL byte")
 	}
-	raw = stripControl(raw)
-	raw = collapseWhitespace(raw)
-	lower := strings.ToLower(raw)
-	if !strings.HasPrefix(lower, "https://") && !strings.HasPrefix(lower, "http://") {
-		return "", errors.New("unsupported url scheme")
+	cleaned := path.Clean(strings.ReplaceAll(userPath, "\\", "/"))
+	if path.IsAbs(cleaned) {
+		return "", errors.New("path must be relative")
 	}
-	// Basic allowlist of characters.
-	for _, r := range raw {
-		if r < 0x20 || r == 0x7F {
-			return "", errors.New("invalid url character")
-		}
+	if len(cleaned) >= 2 && cleaned[1] == ':' {
+		return "", errors.New("path must not contain a drive letter")
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", errors.New("path escapes root")
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	joined := filepath.Join(absRoot, filepath.FromSlash(cleaned))
+	absJoined, err := filepath.Abs(joined)
+	if err != nil {
+		return "", err
+	}
+	if absJoined != absRoot && !strings.HasPrefix(absJoined, absRoot+string(filepath.Separator)) {
+		return "", errors.New("path escapes root")
+	}
+	return absJoined, nil
+}
+
+// SanitizeImageURL enforces a safe image URL (http, https, mailto, or
+// data:image/..., as ImagePolicy allows) with a max length. It
+// is a thin wrapper over SanitizeURL using ImagePolicy, kept for existing
+// callers that don't need a custom URLPolicy.
+func SanitizeImageURL(u string) (string, error) {
+	parsed, err := SanitizeURL(u, ImagePolicy)
+	if err != nil {
+		return "", err
 	}
-	return raw, nil
+	return parsed.String(), nil
 }
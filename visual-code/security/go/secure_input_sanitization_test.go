@@ -0,0 +1,94 @@
+// File: /visual-code/security/go/secure_input_sanitization_test.go
+// Platform: Windows/Linux/Ubuntu, Android/iOS (Go ≥ 1.20)
+// Language: Go (sanitized, production-grade)
+
+package security
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain name", input: "photo.png", want: "photo.png"},
+		{name: "path separators stripped", input: "a/b\\c:d.png", want: "abcd.png"},
+		{name: "empty input", input: "", wantErr: true},
+		{name: "only illegal characters", input: `/\:`, wantErr: true},
+		{name: "reserved name NUL", input: "NUL", wantErr: true},
+		{name: "reserved name lowercase", input: "nul", wantErr: true},
+		{name: "reserved name with extension", input: "con.txt", wantErr: true},
+		{name: "reserved-looking but not reserved", input: "nullable.txt", want: "nullable.txt"},
+		{name: "reserved COM port device", input: "COM1", wantErr: true},
+		{name: "reserved LPT port device", input: "lpt9.log", wantErr: true},
+		{name: "ordinary name containing device-like prefix", input: "comedy.txt", want: "comedy.txt"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SanitizeFilename(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("SanitizeFilename(%q) = %q, want error", tc.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SanitizeFilename(%q) returned error: %v", tc.input, err)
+			}
+			if got != tc.want {
+				t.Fatalf("SanitizeFilename(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveUnderRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "sandbox")
+
+	cases := []struct {
+		name     string
+		userPath string
+		wantErr  bool
+	}{
+		{name: "simple relative path", userPath: "reports/output.txt"},
+		{name: "single segment", userPath: "output.txt"},
+		{name: "dot-relative path", userPath: "./output.txt"},
+		{name: "parent traversal", userPath: "../output.txt", wantErr: true},
+		{name: "deep parent traversal", userPath: "../../../../etc/passwd", wantErr: true},
+		{name: "traversal buried in the middle", userPath: "reports/../../output.txt", wantErr: true},
+		{name: "bare parent segment", userPath: "..", wantErr: true},
+		{name: "absolute unix path", userPath: "/etc/passwd", wantErr: true},
+		{name: "windows drive letter", userPath: `C:\Windows\System32\cmd.exe`, wantErr: true},
+		{name: "UNC-style path", userPath: `\\server\share\file.txt`, wantErr: true},
+		{name: "NUL byte", userPath: "ok\x00.txt", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ResolveUnderRoot(root, tc.userPath)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("ResolveUnderRoot(%q, %q) = %q, want error", root, tc.userPath, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveUnderRoot(%q, %q) returned error: %v", root, tc.userPath, err)
+			}
+			absRoot, err := filepath.Abs(root)
+			if err != nil {
+				t.Fatalf("filepath.Abs(%q) returned error: %v", root, err)
+			}
+			if got != absRoot && !strings.HasPrefix(got, absRoot+string(filepath.Separator)) {
+				t.Fatalf("ResolveUnderRoot(%q, %q) = %q, escapes root %q", root, tc.userPath, got, absRoot)
+			}
+		})
+	}
+}
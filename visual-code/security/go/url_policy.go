@@ -0,0 +1,165 @@
+// File: /visual-code/security/go/url_policy.go
+// Platform: Windows/Linux/Ubuntu, Android/iOS (Go ≥ 1.20)
+// Language: Go (sanitized, production-grade)
+
+package security
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// URLPolicy configures SanitizeURL. The zero value is not usable; start
+// from ImagePolicy or build a policy with explicit allowlists.
+type URLPolicy struct {
+	// AllowedSchemes lists schemes kept as-is (e.g. "https"); anything else
+	// is rejected. Scheme comparison is case-insensitive.
+	AllowedSchemes map[string]bool
+	// AllowedHosts, if non-empty, restricts Host to this allowlist
+	// (case-insensitive, no wildcards). Empty means any host is permitted
+	// subject to BlockPrivateNets.
+	AllowedHosts map[string]bool
+	// BlockPrivateNets rejects URLs whose host resolves to an RFC1918,
+	// loopback, link-local, or ULA address (including an IPv4-mapped IPv6
+	// address wrapping one of those), guarding the image-fetch path against
+	// SSRF into internal services or the cloud-metadata endpoint
+	// (169.254.169.254). SanitizeURL resolves the host once and returns the
+	// hostname, not the resolved addresses, so a later fetch that
+	// re-resolves the hostname (DNS rebinding) is not covered by this check.
+	BlockPrivateNets bool
+	// RequireTLS rejects any scheme other than "https" (and "data:image/..."
+	// when DataImages is also set), for callers that can't tolerate
+	// plaintext fetches.
+	RequireTLS bool
+	// MaxRedirects bounds how many redirects a caller may follow when
+	// fetching the sanitized URL. SanitizeURL does not itself follow
+	// redirects; this is advisory metadata for the HTTP client the caller
+	// builds around the sanitized URL.
+	MaxRedirects int
+	// DataImages permits "data:image/..." URIs to pass without a host
+	// check (there is no host to check).
+	DataImages bool
+}
+
+// ErrUnsafeScheme is returned when a URL's scheme is not in
+// policy.AllowedSchemes, mirroring the conservative scheme-filter approach
+// html/template uses for javascript:/data:/vbscript: hrefs.
+var ErrUnsafeScheme = errors.New("security: unsafe or disallowed URL scheme")
+
+// ErrPrivateNetwork is returned when BlockPrivateNets rejects a host that
+// resolves to a private, loopback, or link-local address.
+var ErrPrivateNetwork = errors.New("security: URL host resolves to a private or link-local network")
+
+// ErrDisallowedHost is returned when AllowedHosts is set and the URL's host
+// is not in it.
+var ErrDisallowedHost = errors.New("security: URL host not in allowlist")
+
+// ImagePolicy is the preset used by SanitizeImageURL: https/http/mailto
+// plus data:image/... URIs, with private-network destinations blocked.
+var ImagePolicy = &URLPolicy{
+	AllowedSchemes:   map[string]bool{"https": true, "http": true, "mailto": true},
+	BlockPrivateNets: true,
+	DataImages:       true,
+}
+
+// SanitizeURL parses u and validates it against policy: scheme must be
+// allowed (data:image/... is special-cased when policy.DataImages is set),
+// host must satisfy AllowedHosts/RequireTLS when configured, and when
+// BlockPrivateNets is set the host is resolved via net.LookupIP and
+// rejected if any resulting address is private, loopback, link-local, or
+// ULA. It returns the parsed *url.URL on success.
+func SanitizeURL(u string, policy *URLPolicy) (*url.URL, error) {
+	if policy == nil {
+		return nil, errors.New("nil URL policy")
+	}
+	raw := strings.TrimSpace(u)
+	if len(raw) == 0 {
+		return nil, errors.New("empty url")
+	}
+	if len(raw) > MaxURLBytes {
+		return nil, errors.New("url too long")
+	}
+	raw = stripControl(raw)
+	for _, r := range raw {
+		if r < 0x20 || r == 0x7F {
+			return nil, errors.New("invalid url character")
+		}
+	}
+
+	if policy.DataImages && strings.HasPrefix(strings.ToLower(raw), "data:image/") {
+		return &url.URL{Opaque: raw}, nil
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	if !policy.AllowedSchemes[scheme] {
+		return nil, ErrUnsafeScheme
+	}
+	if policy.RequireTLS && scheme != "https" {
+		return nil, ErrUnsafeScheme
+	}
+	if scheme == "mailto" {
+		return parsed, nil
+	}
+
+	host := parsed.Hostname()
+	if host == "" {
+		return nil, errors.New("url has no host")
+	}
+	if len(policy.AllowedHosts) > 0 && !hostAllowed(policy.AllowedHosts, host) {
+		return nil, ErrDisallowedHost
+	}
+	if policy.BlockPrivateNets {
+		if err := checkNotPrivate(host); err != nil {
+			return nil, err
+		}
+	}
+	return parsed, nil
+}
+
+// hostAllowed reports whether host matches an entry in allowed,
+// case-insensitively, regardless of the case the caller's map keys happen
+// to use (AllowedHosts is documented as case-insensitive).
+func hostAllowed(allowed map[string]bool, host string) bool {
+	for h := range allowed {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNotPrivate resolves host and rejects it if any address is loopback,
+// private (RFC1918 / ULA fc00::/7), link-local (including the 169.254.0.0/16
+// cloud-metadata range), or an IPv4-mapped IPv6 address wrapping a private
+// IPv4 address.
+func checkNotPrivate(host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		return checkIPNotPrivate(ip)
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if err := checkIPNotPrivate(ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkIPNotPrivate(ip net.IP) error {
+	if v4 := ip.To4(); v4 != nil {
+		ip = v4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return ErrPrivateNetwork
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+// File: /visual-code/security/go/url_policy_test.go
+// Platform: Windows/Linux/Ubuntu, Android/iOS (Go ≥ 1.20)
+// Language: Go (sanitized, production-grade)
+
+package security
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestSanitizeURLSchemeFilter(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		policy  *URLPolicy
+		wantErr error
+	}{
+		{
+			name:   "https allowed",
+			input:  "https://93.184.216.34/image.png",
+			policy: ImagePolicy,
+		},
+		{
+			name:   "http allowed",
+			input:  "http://93.184.216.34/image.png",
+			policy: ImagePolicy,
+		},
+		{
+			name:   "mailto allowed, no host check",
+			input:  "mailto:user@example.com",
+			policy: ImagePolicy,
+		},
+		{
+			name:   "data image allowed",
+			input:  "data:image/png;base64,AAAA",
+			policy: ImagePolicy,
+		},
+		{
+			name:    "javascript scheme rejected",
+			input:   "javascript:alert(1)",
+			policy:  ImagePolicy,
+			wantErr: ErrUnsafeScheme,
+		},
+		{
+			name:    "vbscript scheme rejected",
+			input:   "vbscript:msgbox(1)",
+			policy:  ImagePolicy,
+			wantErr: ErrUnsafeScheme,
+		},
+		{
+			name:    "file scheme rejected",
+			input:   "file:///etc/passwd",
+			policy:  ImagePolicy,
+			wantErr: ErrUnsafeScheme,
+		},
+		{
+			name:    "gopher scheme rejected",
+			input:   "gopher://93.184.216.34/",
+			policy:  ImagePolicy,
+			wantErr: ErrUnsafeScheme,
+		},
+		{
+			name:    "ftp scheme rejected",
+			input:   "ftp://93.184.216.34/",
+			policy:  ImagePolicy,
+			wantErr: ErrUnsafeScheme,
+		},
+		{
+			name:    "non-image data URI rejected by ImagePolicy",
+			input:   "data:text/html,<script>alert(1)</script>",
+			policy:  ImagePolicy,
+			wantErr: ErrUnsafeScheme,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := SanitizeURL(tc.input, tc.policy)
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("SanitizeURL(%q) returned unexpected error: %v", tc.input, err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Fatalf("SanitizeURL(%q) error = %v, want %v", tc.input, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckIPNotPrivate(t *testing.T) {
+	cases := []struct {
+		name      string
+		ip        string
+		wantBlock bool
+	}{
+		{name: "public IPv4", ip: "93.184.216.34", wantBlock: false},
+		{name: "loopback IPv4", ip: "127.0.0.1", wantBlock: true},
+		{name: "RFC1918 10/8", ip: "10.1.2.3", wantBlock: true},
+		{name: "RFC1918 192.168/16", ip: "192.168.1.1", wantBlock: true},
+		{name: "link-local / cloud metadata", ip: "169.254.169.254", wantBlock: true},
+		{name: "unspecified IPv4", ip: "0.0.0.0", wantBlock: true},
+		{name: "public IPv6", ip: "2606:4700:4700::1111", wantBlock: false},
+		{name: "loopback IPv6", ip: "::1", wantBlock: true},
+		{name: "ULA IPv6", ip: "fc00::1", wantBlock: true},
+		{name: "IPv4-mapped IPv6 private", ip: "::ffff:10.0.0.1", wantBlock: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ip := net.ParseIP(tc.ip)
+			if ip == nil {
+				t.Fatalf("net.ParseIP(%q) failed", tc.ip)
+			}
+			err := checkIPNotPrivate(ip)
+			if tc.wantBlock && !errors.Is(err, ErrPrivateNetwork) {
+				t.Fatalf("checkIPNotPrivate(%q) = %v, want ErrPrivateNetwork", tc.ip, err)
+			}
+			if !tc.wantBlock && err != nil {
+				t.Fatalf("checkIPNotPrivate(%q) = %v, want nil", tc.ip, err)
+			}
+		})
+	}
+}
+
+func TestSanitizeURLBlockPrivateNets(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "public IP allowed", input: "https://93.184.216.34/x.png"},
+		{name: "loopback blocked", input: "https://127.0.0.1/x.png", wantErr: ErrPrivateNetwork},
+		{name: "cloud metadata blocked", input: "http://169.254.169.254/latest/meta-data/", wantErr: ErrPrivateNetwork},
+		{name: "RFC1918 blocked", input: "https://10.0.0.5/x.png", wantErr: ErrPrivateNetwork},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := SanitizeURL(tc.input, ImagePolicy)
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("SanitizeURL(%q) returned unexpected error: %v", tc.input, err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Fatalf("SanitizeURL(%q) error = %v, want %v", tc.input, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSanitizeURLAllowedHostsCaseInsensitive(t *testing.T) {
+	policy := &URLPolicy{
+		AllowedSchemes: map[string]bool{"https": true},
+		AllowedHosts:   map[string]bool{"Example.com": true},
+	}
+	if _, err := SanitizeURL("https://example.com/path", policy); err != nil {
+		t.Fatalf("SanitizeURL with differently-cased AllowedHosts entry returned error: %v", err)
+	}
+	if _, err := SanitizeURL("https://EXAMPLE.COM/path", policy); err != nil {
+		t.Fatalf("SanitizeURL with differently-cased host returned error: %v", err)
+	}
+	if _, err := SanitizeURL("https://evil.example/path", policy); !errors.Is(err, ErrDisallowedHost) {
+		t.Fatalf("SanitizeURL for a host outside the allowlist = %v, want ErrDisallowedHost", err)
+	}
+}
+
+func TestSanitizeURLRequireTLS(t *testing.T) {
+	policy := &URLPolicy{
+		AllowedSchemes: map[string]bool{"https": true, "http": true},
+		RequireTLS:     true,
+	}
+	if _, err := SanitizeURL("https://93.184.216.34/x.png", policy); err != nil {
+		t.Fatalf("SanitizeURL(https) with RequireTLS returned error: %v", err)
+	}
+	if _, err := SanitizeURL("http://93.184.216.34/x.png", policy); !errors.Is(err, ErrUnsafeScheme) {
+		t.Fatalf("SanitizeURL(http) with RequireTLS = %v, want ErrUnsafeScheme", err)
+	}
+}
+
+func TestSanitizeImageURL(t *testing.T) {
+	if _, err := SanitizeImageURL("https://93.184.216.34/x.png"); err != nil {
+		t.Fatalf("SanitizeImageURL returned error: %v", err)
+	}
+	if _, err := SanitizeImageURL("javascript:alert(1)"); err == nil {
+		t.Fatal("SanitizeImageURL should reject javascript: URLs")
+	}
+	if _, err := SanitizeImageURL("http://169.254.169.254/latest/meta-data/"); err == nil {
+		t.Fatal("SanitizeImageURL should reject the cloud-metadata address")
+	}
+}